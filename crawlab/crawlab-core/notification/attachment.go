@@ -0,0 +1,89 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// Attachment is a single file attached to an outgoing email. Reader is
+// consumed once, when the message is written out, so callers passing a
+// bytes.Reader/bytes.NewReader over in-memory data should build a fresh one
+// per send.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Reader      io.Reader
+}
+
+// bufferedAttachment is an Attachment whose Reader has already been read
+// into memory. The send daemon buffers attachments once, at Enqueue time,
+// so that retries and dead-letter persistence can each build a fresh
+// Attachment from Data instead of re-reading an already-drained Reader.
+type bufferedAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// bufferAttachments reads each attachment's Reader fully into memory.
+func bufferAttachments(attachments []Attachment) ([]bufferedAttachment, error) {
+	if len(attachments) == 0 {
+		return nil, nil
+	}
+
+	buffered := make([]bufferedAttachment, len(attachments))
+	for i, a := range attachments {
+		data, err := io.ReadAll(a.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to buffer attachment %q: %w", a.Filename, err)
+		}
+		buffered[i] = bufferedAttachment{Filename: a.Filename, ContentType: a.ContentType, Data: data}
+	}
+	return buffered, nil
+}
+
+// toAttachments rebuilds the []Attachment slice sendOptions needs, wrapping
+// each Data in a fresh bytes.Reader so the same bufferedAttachment can back
+// any number of send attempts.
+func toAttachments(buffered []bufferedAttachment) []Attachment {
+	if len(buffered) == 0 {
+		return nil
+	}
+
+	out := make([]Attachment, len(buffered))
+	for i, b := range buffered {
+		out[i] = Attachment{Filename: b.Filename, ContentType: b.ContentType, Reader: bytes.NewReader(b.Data)}
+	}
+	return out
+}
+
+// deadLetterAttachments converts buffered attachments into the persisted
+// DeadLetterAttachment form, so an envelope's attachments survive a
+// dead-letter write instead of being silently dropped.
+func deadLetterAttachments(buffered []bufferedAttachment) []DeadLetterAttachment {
+	if len(buffered) == 0 {
+		return nil
+	}
+
+	out := make([]DeadLetterAttachment, len(buffered))
+	for i, b := range buffered {
+		out[i] = DeadLetterAttachment{Filename: b.Filename, ContentType: b.ContentType, Data: b.Data}
+	}
+	return out
+}
+
+// SendMailWithAttachments behaves like SendMail but attaches each of
+// attachments to the outgoing email via gomail's SetCopyFunc, so the
+// content doesn't need to live on disk first.
+func SendMailWithAttachments(s *Setting, to, cc, title, content string, attachments []Attachment) error {
+	notifier := NewSMTPNotifier(s)
+	return notifier.Send(context.Background(), Message{
+		To:          to,
+		Cc:          cc,
+		Subject:     title,
+		Content:     content,
+		Attachments: attachments,
+	})
+}
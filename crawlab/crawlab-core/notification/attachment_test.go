@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestBufferAttachments(t *testing.T) {
+	in := []Attachment{
+		{Filename: "a.csv", ContentType: "text/csv", Reader: bytes.NewReader([]byte("a,b\n1,2\n"))},
+		{Filename: "b.json", ContentType: "application/json", Reader: bytes.NewReader([]byte(`{"x":1}`))},
+	}
+
+	buffered, err := bufferAttachments(in)
+	if err != nil {
+		t.Fatalf("bufferAttachments returned error: %s", err)
+	}
+	if len(buffered) != 2 {
+		t.Fatalf("len(buffered) = %d, want 2", len(buffered))
+	}
+	if string(buffered[0].Data) != "a,b\n1,2\n" || buffered[0].Filename != "a.csv" {
+		t.Errorf("buffered[0] = %+v, want data from the first attachment", buffered[0])
+	}
+	if string(buffered[1].Data) != `{"x":1}` {
+		t.Errorf("buffered[1].Data = %q, want %q", buffered[1].Data, `{"x":1}`)
+	}
+}
+
+func TestBufferAttachmentsEmpty(t *testing.T) {
+	buffered, err := bufferAttachments(nil)
+	if err != nil || buffered != nil {
+		t.Fatalf("bufferAttachments(nil) = (%v, %v), want (nil, nil)", buffered, err)
+	}
+}
+
+type erroringReader struct{}
+
+func (erroringReader) Read(p []byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestBufferAttachmentsReadError(t *testing.T) {
+	_, err := bufferAttachments([]Attachment{{Filename: "bad", Reader: erroringReader{}}})
+	if err == nil {
+		t.Fatal("bufferAttachments should propagate a read error")
+	}
+}
+
+func TestToAttachmentsRoundTrip(t *testing.T) {
+	buffered := []bufferedAttachment{
+		{Filename: "a.csv", ContentType: "text/csv", Data: []byte("data")},
+	}
+
+	out := toAttachments(buffered)
+	if len(out) != 1 {
+		t.Fatalf("len(out) = %d, want 1", len(out))
+	}
+	got, err := io.ReadAll(out[0].Reader)
+	if err != nil || string(got) != "data" {
+		t.Errorf("toAttachments readback = (%q, %v), want (%q, nil)", got, err, "data")
+	}
+
+	// A second call must build a fresh, unconsumed Reader.
+	out2 := toAttachments(buffered)
+	got2, err := io.ReadAll(out2[0].Reader)
+	if err != nil || string(got2) != "data" {
+		t.Errorf("second toAttachments readback = (%q, %v), want (%q, nil)", got2, err, "data")
+	}
+}
+
+func TestDeadLetterAttachments(t *testing.T) {
+	buffered := []bufferedAttachment{
+		{Filename: "a.csv", ContentType: "text/csv", Data: []byte("data")},
+	}
+
+	out := deadLetterAttachments(buffered)
+	if len(out) != 1 || out[0].Filename != "a.csv" || string(out[0].Data) != "data" {
+		t.Errorf("deadLetterAttachments = %+v, want one entry carrying the buffered data", out)
+	}
+
+	if deadLetterAttachments(nil) != nil {
+		t.Error("deadLetterAttachments(nil) should return nil")
+	}
+}
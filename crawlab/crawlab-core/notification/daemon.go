@@ -0,0 +1,333 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"net/textproto"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+	"golang.org/x/time/rate"
+	"gopkg.in/gomail.v2"
+)
+
+const (
+	// defaultQueueSize is the number of pending Envelopes the daemon buffers
+	// before Enqueue starts rejecting new ones with an error.
+	defaultQueueSize = 1000
+
+	// defaultIdleTimeout is how long an SMTP connection is kept open while
+	// the queue is empty before the daemon closes it.
+	defaultIdleTimeout = 30 * time.Second
+
+	// defaultMaxAttempts is how many send attempts (the first one included)
+	// an Envelope gets before it is written to the dead-letter store.
+	defaultMaxAttempts = 5
+
+	// defaultRecipientRate caps how often a single recipient can be mailed.
+	defaultRecipientRate = rate.Limit(1.0 / 2.0) // one message every 2s
+
+	// limiterIdleEvict is how long a recipient's rate limiter can sit unused
+	// before it is evicted, so the daemon's memory doesn't grow without
+	// bound as new "to" addresses show up over the life of the process.
+	limiterIdleEvict = 10 * time.Minute
+
+	// limiterSweepInterval is how often evictStaleLimiters runs.
+	limiterSweepInterval = time.Minute
+)
+
+// sendDaemon is a long-running sender that owns a single SMTP connection,
+// reusing it across Envelopes instead of dialing per send, per the gomail
+// daemon pattern. It is package-private; callers only see Enqueue.
+type sendDaemon struct {
+	queue      chan Envelope
+	deadLetter DeadLetterStore
+
+	mu       sync.Mutex
+	limiters map[string]*limiterEntry
+
+	startOnce sync.Once
+}
+
+// limiterEntry pairs a recipient's rate.Limiter with the last time it was
+// used, so evictStaleLimiters can reclaim limiters for addresses the daemon
+// hasn't mailed in a while.
+type limiterEntry struct {
+	limiter  *rate.Limiter
+	lastUsed time.Time
+}
+
+var defaultDaemon = &sendDaemon{
+	queue:    make(chan Envelope, defaultQueueSize),
+	limiters: map[string]*limiterEntry{},
+}
+
+// SetDeadLetterStore installs the store used for Envelopes that exhaust
+// their retries. The assignment is not synchronized with the daemon
+// goroutine, so call it during application start-up before the first Enqueue.
+func SetDeadLetterStore(store DeadLetterStore) {
+	defaultDaemon.deadLetter = store
+}
+
+// Enqueue is the public entry point for sending a notification. It pushes
+// msg onto the send daemon's queue and returns once it is accepted, well
+// before it is actually delivered; delivery failures land in the
+// dead-letter store rather than being returned to the caller.
+func Enqueue(s *Setting, msg Message) error {
+	defaultDaemon.start()
+
+	attachments, err := bufferAttachments(msg.Attachments)
+	if err != nil {
+		return err
+	}
+	msg.Attachments = nil
+
+	select {
+	case defaultDaemon.queue <- Envelope{Setting: s, Message: msg, Attachments: attachments, CreatedAt: time.Now()}:
+		return nil
+	default:
+		return errors.New("notification queue is full")
+	}
+}
+
+func (d *sendDaemon) start() {
+	d.startOnce.Do(func() {
+		go d.run()
+	})
+}
+
+func (d *sendDaemon) run() {
+	var conn *smtpConn
+
+	idle := time.NewTimer(defaultIdleTimeout)
+	defer idle.Stop()
+
+	sweep := time.NewTicker(limiterSweepInterval)
+	defer sweep.Stop()
+
+	for {
+		select {
+		case env := <-d.queue:
+			idle.Reset(defaultIdleTimeout)
+
+			if delay, throttled := d.reserveRecipient(env.Message.To); throttled {
+				d.deferEnvelope(env, delay)
+				continue
+			}
+
+			var err error
+			conn, err = d.send(conn, env)
+			if err != nil {
+				d.retryOrDeadLetter(env, err)
+			}
+
+		case <-idle.C:
+			if conn != nil {
+				conn.Close()
+				conn = nil
+			}
+			idle.Reset(defaultIdleTimeout)
+
+		case <-sweep.C:
+			d.evictStaleLimiters()
+		}
+	}
+}
+
+// deferEnvelope re-queues env after delay instead of blocking the single
+// worker goroutine on it, so a throttled recipient doesn't head-of-line
+// block delivery to every other recipient queued behind it.
+func (d *sendDaemon) deferEnvelope(env Envelope, delay time.Duration) {
+	time.AfterFunc(delay, func() {
+		select {
+		case d.queue <- env:
+		default:
+			d.saveDeadLetter(env, errors.New("notification queue full while re-queuing throttled envelope"))
+		}
+	})
+}
+
+// send delivers env through the provider configured on env.Setting. Only
+// ProviderSMTP (including settings that predate the Provider field) takes
+// the persistent-connection fast path below; every other provider is
+// dispatched through its Notifier directly, since connection reuse is
+// SMTP-specific. Per-recipient rate limiting in run applies to every
+// provider, while retries only happen for transient SMTP replies.
+func (d *sendDaemon) send(conn *smtpConn, env Envelope) (*smtpConn, error) {
+	if resolvedProvider(env.Setting) != ProviderSMTP {
+		return conn, Dispatch(context.Background(), env.Setting, env.Message)
+	}
+
+	html, text, err := renderEmail(env.Message.Content)
+	if err != nil {
+		return conn, err
+	}
+
+	cfg := smtpAuthenticationFromSetting(env.Setting)
+	options := sendOptions{
+		To:                 env.Message.To,
+		Cc:                 env.Message.Cc,
+		Subject:            env.Message.Subject,
+		ReplyTo:            env.Message.ReplyTo,
+		ListUnsubscribeURL: env.Message.ListUnsubscribeURL,
+		Attachments:        toAttachments(env.Attachments),
+	}
+	if err := cfg.validate(options); err != nil {
+		return conn, err
+	}
+
+	if conn == nil || !conn.matches(cfg) {
+		if conn != nil {
+			conn.Close()
+		}
+		conn, err = dialSMTP(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m := buildMessage(cfg, options, html, text)
+	return conn, gomail.Send(conn.sc, m)
+}
+
+// retryOrDeadLetter re-queues env with backoff for transient 4xx SMTP
+// errors, or persists it to the dead-letter store once attempts are
+// exhausted or the error looks permanent.
+func (d *sendDaemon) retryOrDeadLetter(env Envelope, sendErr error) {
+	env.Attempts++
+
+	if isTransient(sendErr) && env.Attempts < defaultMaxAttempts {
+		delay := backoff(env.Attempts)
+		log.Warnf("notification send failed (attempt %d/%d), retrying in %s: %s", env.Attempts, defaultMaxAttempts, delay, sendErr)
+		time.AfterFunc(delay, func() {
+			select {
+			case d.queue <- env:
+			default:
+				d.saveDeadLetter(env, sendErr)
+			}
+		})
+		return
+	}
+
+	d.saveDeadLetter(env, sendErr)
+}
+
+func (d *sendDaemon) saveDeadLetter(env Envelope, sendErr error) {
+	log.Errorf("notification send failed permanently after %d attempt(s): %s", env.Attempts, sendErr)
+
+	if d.deadLetter == nil {
+		return
+	}
+
+	entry := DeadLetterEntry{
+		To:          env.Message.To,
+		Cc:          env.Message.Cc,
+		Subject:     env.Message.Subject,
+		Content:     env.Message.Content,
+		Attachments: deadLetterAttachments(env.Attachments),
+		Provider:    string(env.Setting.Provider),
+		Attempts:    env.Attempts,
+		LastError:   sendErr.Error(),
+		CreatedAt:   env.CreatedAt,
+		FailedAt:    time.Now(),
+	}
+	if err := d.deadLetter.Save(context.Background(), entry); err != nil {
+		log.Errorf("failed to persist dead-letter notification: %s", err)
+	}
+}
+
+// reserveRecipient reports whether sending to "to" right now would exceed
+// its rate limit. If it would, the required wait is returned and the
+// reservation is cancelled (nothing was consumed) so the caller can defer
+// the send instead of blocking on it.
+func (d *sendDaemon) reserveRecipient(to string) (delay time.Duration, throttled bool) {
+	limiter := d.limiterFor(to)
+
+	res := limiter.Reserve()
+	if delay := res.Delay(); delay > 0 {
+		res.Cancel()
+		return delay, true
+	}
+	return 0, false
+}
+
+// limiterFor returns the rate.Limiter for to, creating one on first use and
+// recording this as its most recent use for evictStaleLimiters.
+func (d *sendDaemon) limiterFor(to string) *rate.Limiter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry, ok := d.limiters[to]
+	if !ok {
+		entry = &limiterEntry{limiter: rate.NewLimiter(defaultRecipientRate, 1)}
+		d.limiters[to] = entry
+	}
+	entry.lastUsed = time.Now()
+	return entry.limiter
+}
+
+// evictStaleLimiters drops limiters for recipients that haven't been mailed
+// in limiterIdleEvict, so the map doesn't grow without bound over the life
+// of this long-running daemon.
+func (d *sendDaemon) evictStaleLimiters() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cutoff := time.Now().Add(-limiterIdleEvict)
+	for to, entry := range d.limiters {
+		if entry.lastUsed.Before(cutoff) {
+			delete(d.limiters, to)
+		}
+	}
+}
+
+// backoff returns an exponential delay for the given attempt number,
+// starting at 1s and doubling each time.
+func backoff(attempt int) time.Duration {
+	d := time.Second
+	for i := 1; i < attempt; i++ {
+		d *= 2
+	}
+	return d
+}
+
+// isTransient reports whether err looks like a transient SMTP failure (4xx
+// reply code) worth retrying, as opposed to a permanent rejection (5xx) or
+// a config error.
+func isTransient(err error) bool {
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		return protoErr.Code >= 400 && protoErr.Code < 500
+	}
+	return false
+}
+
+// smtpConn wraps an open gomail.SendCloser along with the config it was
+// dialed with, so the daemon can tell whether a queued Envelope can reuse it.
+type smtpConn struct {
+	sc     gomail.SendCloser
+	config smtpAuthentication
+}
+
+func (c *smtpConn) matches(cfg smtpAuthentication) bool {
+	return c.config == cfg
+}
+
+func (c *smtpConn) Close() {
+	if err := c.sc.Close(); err != nil {
+		log.Warnf("failed to close SMTP connection: %s", err)
+	}
+}
+
+func dialSMTP(cfg smtpAuthentication) (*smtpConn, error) {
+	d, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := d.Dial()
+	if err != nil {
+		return nil, err
+	}
+	return &smtpConn{sc: sc, config: cfg}, nil
+}
@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DeadLetterStore persists Envelopes that failed all their retries so they
+// survive a restart instead of silently vanishing from the in-memory queue.
+type DeadLetterStore interface {
+	Save(ctx context.Context, entry DeadLetterEntry) error
+}
+
+// MongoDeadLetterStore is the default DeadLetterStore, backed by a single
+// MongoDB collection.
+type MongoDeadLetterStore struct {
+	col *mongo.Collection
+}
+
+// NewMongoDeadLetterStore wraps col as a DeadLetterStore.
+func NewMongoDeadLetterStore(col *mongo.Collection) *MongoDeadLetterStore {
+	return &MongoDeadLetterStore{col: col}
+}
+
+func (s *MongoDeadLetterStore) Save(ctx context.Context, entry DeadLetterEntry) error {
+	_, err := s.col.InsertOne(ctx, entry)
+	return err
+}
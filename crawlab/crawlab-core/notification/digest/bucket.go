@@ -0,0 +1,34 @@
+package digest
+
+// DigestBucket accumulates Events for a single user between two scheduler
+// runs. It is the unit Render turns into one recap email.
+type DigestBucket struct {
+	UserId string
+	Events []Event
+}
+
+// NewDigestBucket returns an empty bucket for userId.
+func NewDigestBucket(userId string) *DigestBucket {
+	return &DigestBucket{UserId: userId}
+}
+
+// Add appends e to the bucket.
+func (b *DigestBucket) Add(e Event) {
+	b.Events = append(b.Events, e)
+}
+
+// IsEmpty reports whether the bucket has nothing worth emailing about, so
+// the scheduler can skip sending.
+func (b *DigestBucket) IsEmpty() bool {
+	return len(b.Events) == 0
+}
+
+// ByType groups the bucket's events by EventType, preserving arrival order
+// within each group, for Render to turn into per-section tables.
+func (b *DigestBucket) ByType() map[EventType][]Event {
+	grouped := map[EventType][]Event{}
+	for _, e := range b.Events {
+		grouped[e.Type] = append(grouped[e.Type], e)
+	}
+	return grouped
+}
@@ -0,0 +1,34 @@
+package digest
+
+import (
+	"context"
+	"time"
+
+	"github.com/crawlab-team/crawlab-core/notification"
+)
+
+// Subscription is one user's opt-in to receive a digest, and how often.
+// CronExpr follows robfig/cron syntax, e.g. "0 * * * *" for hourly,
+// "0 9 * * *" for daily at 9am, "0 9 * * 1" for weekly on Monday.
+type Subscription struct {
+	UserId   string
+	Email    string // recipient address; notification.Setting only carries sender-side config
+	CronExpr string
+}
+
+// SubscriptionLister enumerates the users who have a digest configured.
+type SubscriptionLister interface {
+	ListSubscriptions(ctx context.Context) ([]Subscription, error)
+}
+
+// Collector gathers the Events a user accumulated since the given
+// watermark into a DigestBucket.
+type Collector interface {
+	Collect(ctx context.Context, userId string, since time.Time) (*DigestBucket, error)
+}
+
+// SettingProvider resolves the notification.Setting a digest should be sent
+// through for a given user.
+type SettingProvider interface {
+	GetSetting(ctx context.Context, userId string) (*notification.Setting, error)
+}
@@ -0,0 +1,25 @@
+package digest
+
+import "time"
+
+// EventType identifies the kind of spider event a DigestBucket groups.
+type EventType string
+
+const (
+	EventTaskCompleted EventType = "task_completed"
+	EventTaskFailed    EventType = "task_failed"
+	EventNodeOffline   EventType = "node_offline"
+	EventNewResults    EventType = "new_results"
+)
+
+// Event is a single occurrence collected into a user's DigestBucket between
+// scheduler runs.
+type Event struct {
+	Type       EventType
+	UserId     string
+	SpiderId   string
+	SpiderName string
+	Message    string
+	Count      int // meaningful for EventNewResults
+	Timestamp  time.Time
+}
@@ -0,0 +1,63 @@
+package digest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sectionTitles gives each EventType the heading used in a rendered digest.
+var sectionTitles = map[EventType]string{
+	EventTaskCompleted: "Tasks Completed",
+	EventTaskFailed:    "Tasks Failed",
+	EventNodeOffline:   "Nodes Offline",
+	EventNewResults:    "New Results",
+}
+
+// sectionOrder fixes the order sections appear in, regardless of map
+// iteration order.
+var sectionOrder = []EventType{EventTaskCompleted, EventTaskFailed, EventNodeOffline, EventNewResults}
+
+// RenderSubject builds the email subject line summarizing bucket's
+// composition, e.g. "Crawlab Digest: 3 Tasks Completed, 1 Tasks Failed".
+func RenderSubject(bucket *DigestBucket) string {
+	grouped := bucket.ByType()
+	var parts []string
+	for _, t := range sectionOrder {
+		if events := grouped[t]; len(events) > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", len(events), sectionTitles[t]))
+		}
+	}
+	return "Crawlab Digest: " + strings.Join(parts, ", ")
+}
+
+// RenderContent renders bucket as markdown, one table per non-empty
+// EventType section. It is passed straight through as notification.Message
+// Content, which the SMTP notifier's hermes FreeMarkdown pipeline turns into
+// the final HTML/text bodies — the same rendering path every other
+// notification goes through, just with a table per section instead of a
+// single paragraph.
+func RenderContent(bucket *DigestBucket) string {
+	grouped := bucket.ByType()
+
+	var b strings.Builder
+	for _, t := range sectionOrder {
+		events := grouped[t]
+		if len(events) == 0 {
+			continue
+		}
+
+		b.WriteString(fmt.Sprintf("## %s\n\n", sectionTitles[t]))
+		b.WriteString("| Spider | Detail |\n")
+		b.WriteString("| --- | --- |\n")
+		for _, e := range events {
+			detail := e.Message
+			if t == EventNewResults {
+				detail = fmt.Sprintf("%d new results", e.Count)
+			}
+			b.WriteString(fmt.Sprintf("| %s | %s |\n", e.SpiderName, detail))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDigestBucketByType(t *testing.T) {
+	b := NewDigestBucket("user-1")
+	b.Add(Event{Type: EventTaskCompleted, SpiderName: "spider-a"})
+	b.Add(Event{Type: EventTaskCompleted, SpiderName: "spider-b"})
+	b.Add(Event{Type: EventTaskFailed, SpiderName: "spider-c"})
+
+	grouped := b.ByType()
+
+	if got := len(grouped[EventTaskCompleted]); got != 2 {
+		t.Errorf("len(grouped[EventTaskCompleted]) = %d, want 2", got)
+	}
+	if got := len(grouped[EventTaskFailed]); got != 1 {
+		t.Errorf("len(grouped[EventTaskFailed]) = %d, want 1", got)
+	}
+	if got := len(grouped[EventNodeOffline]); got != 0 {
+		t.Errorf("len(grouped[EventNodeOffline]) = %d, want 0", got)
+	}
+}
+
+func TestRenderSubject(t *testing.T) {
+	b := NewDigestBucket("user-1")
+	b.Add(Event{Type: EventTaskCompleted})
+	b.Add(Event{Type: EventTaskCompleted})
+	b.Add(Event{Type: EventTaskFailed})
+
+	subject := RenderSubject(b)
+
+	if !strings.Contains(subject, "2 Tasks Completed") {
+		t.Errorf("subject %q missing completed count", subject)
+	}
+	if !strings.Contains(subject, "1 Tasks Failed") {
+		t.Errorf("subject %q missing failed count", subject)
+	}
+	if strings.Contains(subject, "Nodes Offline") {
+		t.Errorf("subject %q should omit empty sections", subject)
+	}
+}
+
+func TestRenderSubjectEmpty(t *testing.T) {
+	if got := RenderSubject(NewDigestBucket("user-1")); got != "Crawlab Digest: " {
+		t.Errorf("RenderSubject(empty) = %q, want %q", got, "Crawlab Digest: ")
+	}
+}
+
+func TestRenderContent(t *testing.T) {
+	b := NewDigestBucket("user-1")
+	b.Add(Event{Type: EventTaskCompleted, SpiderName: "spider-a", Message: "finished in 3m"})
+	b.Add(Event{Type: EventNewResults, SpiderName: "spider-a", Count: 42})
+
+	content := RenderContent(b)
+
+	if !strings.Contains(content, "## Tasks Completed") {
+		t.Errorf("content missing Tasks Completed section:\n%s", content)
+	}
+	if !strings.Contains(content, "spider-a") || !strings.Contains(content, "finished in 3m") {
+		t.Errorf("content missing task completed row:\n%s", content)
+	}
+	if !strings.Contains(content, "42 new results") {
+		t.Errorf("content missing new results count:\n%s", content)
+	}
+	if strings.Contains(content, "## Tasks Failed") {
+		t.Errorf("content should omit empty sections:\n%s", content)
+	}
+}
+
+func TestRenderContentEmpty(t *testing.T) {
+	if got := RenderContent(NewDigestBucket("user-1")); got != "" {
+		t.Errorf("RenderContent(empty) = %q, want empty string", got)
+	}
+}
@@ -0,0 +1,120 @@
+package digest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/apex/log"
+	"github.com/crawlab-team/crawlab-core/notification"
+	"github.com/robfig/cron/v3"
+)
+
+// Scheduler drives one digest email per subscribed user on that user's own
+// cron schedule, batching whatever Events the Collector accumulated since
+// the last run instead of sending one email per event.
+type Scheduler struct {
+	subscriptions SubscriptionLister
+	collector     Collector
+	watermarks    WatermarkStore
+	settings      SettingProvider
+
+	// unsubscribeBaseURL, if set, is used to build each digest's
+	// List-Unsubscribe header as "<unsubscribeBaseURL>?user_id=<userId>".
+	unsubscribeBaseURL string
+
+	cron *cron.Cron
+}
+
+// NewScheduler wires a Scheduler from its four collaborators: where
+// subscriptions come from, how events are gathered, where watermarks are
+// persisted, and how to resolve each user's notification.Setting.
+func NewScheduler(subscriptions SubscriptionLister, collector Collector, watermarks WatermarkStore, settings SettingProvider) *Scheduler {
+	return &Scheduler{
+		subscriptions: subscriptions,
+		collector:     collector,
+		watermarks:    watermarks,
+		settings:      settings,
+		cron:          cron.New(),
+	}
+}
+
+// SetUnsubscribeBaseURL configures the Crawlab HTTP endpoint that toggles a
+// user's notification preference, used to populate List-Unsubscribe. Call
+// it before Start; leaving it unset omits the header.
+func (s *Scheduler) SetUnsubscribeBaseURL(url string) {
+	s.unsubscribeBaseURL = url
+}
+
+// Start loads the current subscriptions and registers one cron entry per
+// user, then starts the underlying cron scheduler. It returns once
+// registration is done; the scheduler itself keeps running until ctx is
+// cancelled.
+func (s *Scheduler) Start(ctx context.Context) error {
+	subs, err := s.subscriptions.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		sub := sub
+		if _, err := s.cron.AddFunc(sub.CronExpr, func() {
+			s.run(ctx, sub.UserId, sub.Email)
+		}); err != nil {
+			return fmt.Errorf("invalid digest schedule %q for user %s: %w", sub.CronExpr, sub.UserId, err)
+		}
+	}
+
+	s.cron.Start()
+
+	go func() {
+		<-ctx.Done()
+		s.cron.Stop()
+	}()
+
+	return nil
+}
+
+// run collects userId's events since their last watermark and, if there's
+// anything to report, renders and dispatches one digest email to email then
+// advances the watermark.
+func (s *Scheduler) run(ctx context.Context, userId, email string) {
+	since, err := s.watermarks.Get(ctx, userId)
+	if err != nil {
+		log.Errorf("failed to load digest watermark for user %s: %s", userId, err)
+		return
+	}
+
+	bucket, err := s.collector.Collect(ctx, userId, since)
+	if err != nil {
+		log.Errorf("failed to collect digest events for user %s: %s", userId, err)
+		return
+	}
+
+	if bucket.IsEmpty() {
+		return
+	}
+
+	setting, err := s.settings.GetSetting(ctx, userId)
+	if err != nil {
+		log.Errorf("failed to load notification setting for user %s: %s", userId, err)
+		return
+	}
+
+	msg := notification.Message{
+		To:      email,
+		Subject: RenderSubject(bucket),
+		Content: RenderContent(bucket),
+	}
+	if s.unsubscribeBaseURL != "" {
+		msg.ListUnsubscribeURL = fmt.Sprintf("%s?user_id=%s", s.unsubscribeBaseURL, userId)
+	}
+	if err := notification.Enqueue(setting, msg); err != nil {
+		log.Errorf("failed to enqueue digest for user %s: %s", userId, err)
+		return
+	}
+
+	if err := s.watermarks.Set(ctx, userId, time.Now()); err != nil {
+		log.Errorf("failed to advance digest watermark for user %s: %s", userId, err)
+	}
+}
@@ -0,0 +1,121 @@
+package digest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/crawlab-team/crawlab-core/notification"
+)
+
+type fakeWatermarkStore struct {
+	get    time.Time
+	getErr error
+	sets   map[string]time.Time
+}
+
+func (f *fakeWatermarkStore) Get(ctx context.Context, userId string) (time.Time, error) {
+	return f.get, f.getErr
+}
+
+func (f *fakeWatermarkStore) Set(ctx context.Context, userId string, t time.Time) error {
+	if f.sets == nil {
+		f.sets = map[string]time.Time{}
+	}
+	f.sets[userId] = t
+	return nil
+}
+
+type fakeCollector struct {
+	bucket *DigestBucket
+	err    error
+}
+
+func (f *fakeCollector) Collect(ctx context.Context, userId string, since time.Time) (*DigestBucket, error) {
+	return f.bucket, f.err
+}
+
+type fakeSettingProvider struct {
+	setting *notification.Setting
+	err     error
+}
+
+func (f *fakeSettingProvider) GetSetting(ctx context.Context, userId string) (*notification.Setting, error) {
+	return f.setting, f.err
+}
+
+func newTestScheduler(watermarks *fakeWatermarkStore, collector *fakeCollector, settings *fakeSettingProvider) *Scheduler {
+	return NewScheduler(nil, collector, watermarks, settings)
+}
+
+func TestRunSkipsOnWatermarkError(t *testing.T) {
+	watermarks := &fakeWatermarkStore{getErr: errors.New("boom")}
+	collector := &fakeCollector{bucket: NewDigestBucket("u1")}
+	settings := &fakeSettingProvider{setting: &notification.Setting{}}
+
+	s := newTestScheduler(watermarks, collector, settings)
+	s.run(context.Background(), "u1", "u1@example.com")
+
+	if len(watermarks.sets) != 0 {
+		t.Error("run should not advance the watermark when loading it failed")
+	}
+}
+
+func TestRunSkipsOnCollectError(t *testing.T) {
+	watermarks := &fakeWatermarkStore{}
+	collector := &fakeCollector{err: errors.New("boom")}
+	settings := &fakeSettingProvider{setting: &notification.Setting{}}
+
+	s := newTestScheduler(watermarks, collector, settings)
+	s.run(context.Background(), "u1", "u1@example.com")
+
+	if len(watermarks.sets) != 0 {
+		t.Error("run should not advance the watermark when collecting events failed")
+	}
+}
+
+func TestRunSkipsOnEmptyBucket(t *testing.T) {
+	watermarks := &fakeWatermarkStore{}
+	collector := &fakeCollector{bucket: NewDigestBucket("u1")}
+	settings := &fakeSettingProvider{setting: &notification.Setting{}}
+
+	s := newTestScheduler(watermarks, collector, settings)
+	s.run(context.Background(), "u1", "u1@example.com")
+
+	if len(watermarks.sets) != 0 {
+		t.Error("run should not advance the watermark or send anything for an empty bucket")
+	}
+}
+
+func TestRunSkipsOnSettingError(t *testing.T) {
+	bucket := NewDigestBucket("u1")
+	bucket.Add(Event{Type: EventTaskCompleted, SpiderName: "spider-a"})
+
+	watermarks := &fakeWatermarkStore{}
+	collector := &fakeCollector{bucket: bucket}
+	settings := &fakeSettingProvider{err: errors.New("boom")}
+
+	s := newTestScheduler(watermarks, collector, settings)
+	s.run(context.Background(), "u1", "u1@example.com")
+
+	if len(watermarks.sets) != 0 {
+		t.Error("run should not advance the watermark when loading the setting failed")
+	}
+}
+
+func TestRunAdvancesWatermarkAfterEnqueue(t *testing.T) {
+	bucket := NewDigestBucket("u1")
+	bucket.Add(Event{Type: EventTaskCompleted, SpiderName: "spider-a"})
+
+	watermarks := &fakeWatermarkStore{}
+	collector := &fakeCollector{bucket: bucket}
+	settings := &fakeSettingProvider{setting: &notification.Setting{}}
+
+	s := newTestScheduler(watermarks, collector, settings)
+	s.run(context.Background(), "u1", "u1@example.com")
+
+	if _, ok := watermarks.sets["u1"]; !ok {
+		t.Error("run should advance the watermark once a non-empty digest has been enqueued")
+	}
+}
@@ -0,0 +1,58 @@
+package digest
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WatermarkStore persists the "last sent" time per user so a restarted
+// Scheduler doesn't re-collect events it already emailed out.
+type WatermarkStore interface {
+	Get(ctx context.Context, userId string) (time.Time, error)
+	Set(ctx context.Context, userId string, t time.Time) error
+}
+
+// watermarkDoc is the MongoDB representation of a single user's watermark.
+type watermarkDoc struct {
+	UserId string    `bson:"user_id"`
+	SentAt time.Time `bson:"sent_at"`
+}
+
+// MongoWatermarkStore is the default WatermarkStore, backed by a single
+// MongoDB collection keyed on user_id.
+type MongoWatermarkStore struct {
+	col *mongo.Collection
+}
+
+// NewMongoWatermarkStore wraps col as a WatermarkStore.
+func NewMongoWatermarkStore(col *mongo.Collection) *MongoWatermarkStore {
+	return &MongoWatermarkStore{col: col}
+}
+
+// Get returns the zero time if userId has no watermark yet, meaning the
+// next Collect call should gather everything since the beginning of time.
+func (s *MongoWatermarkStore) Get(ctx context.Context, userId string) (time.Time, error) {
+	var doc watermarkDoc
+	err := s.col.FindOne(ctx, bson.M{"user_id": userId}).Decode(&doc)
+	if err == mongo.ErrNoDocuments {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return doc.SentAt, nil
+}
+
+func (s *MongoWatermarkStore) Set(ctx context.Context, userId string, t time.Time) error {
+	_, err := s.col.UpdateOne(
+		ctx,
+		bson.M{"user_id": userId},
+		bson.M{"$set": watermarkDoc{UserId: userId, SentAt: t}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
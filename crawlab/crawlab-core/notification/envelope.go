@@ -0,0 +1,43 @@
+package notification
+
+import "time"
+
+// Envelope is the unit of work pushed onto the send daemon's queue. It
+// carries everything Send needs to retry independently of the original
+// caller, which has long since returned by the time the daemon processes it.
+//
+// Attachments holds env.Message.Attachments already read into memory: an
+// Attachment.Reader is consumed once, but an Envelope may be sent more than
+// once (retries) or persisted to the dead-letter store, so the daemon needs
+// to be able to rebuild a fresh Attachment.Reader on every attempt.
+type Envelope struct {
+	Setting     *Setting
+	Message     Message
+	Attachments []bufferedAttachment
+	Attempts    int
+	CreatedAt   time.Time
+}
+
+// DeadLetterAttachment is the persisted form of a bufferedAttachment, kept
+// alongside its DeadLetterEntry so an attachment isn't silently dropped when
+// the Envelope carrying it exhausts its retries.
+type DeadLetterAttachment struct {
+	Filename    string `bson:"filename" json:"filename"`
+	ContentType string `bson:"content_type" json:"content_type"`
+	Data        []byte `bson:"data" json:"data"`
+}
+
+// DeadLetterEntry records an Envelope that exhausted its retries so it can
+// be inspected or replayed later.
+type DeadLetterEntry struct {
+	To          string                 `bson:"to" json:"to"`
+	Cc          string                 `bson:"cc" json:"cc"`
+	Subject     string                 `bson:"subject" json:"subject"`
+	Content     string                 `bson:"content" json:"content"`
+	Attachments []DeadLetterAttachment `bson:"attachments" json:"attachments"`
+	Provider    string                 `bson:"provider" json:"provider"`
+	Attempts    int                    `bson:"attempts" json:"attempts"`
+	LastError   string                 `bson:"last_error" json:"last_error"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+	FailedAt    time.Time              `bson:"failed_at" json:"failed_at"`
+}
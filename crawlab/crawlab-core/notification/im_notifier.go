@@ -0,0 +1,130 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IMSetting holds the webhook URLs for the IM channels Crawlab users
+// commonly wire up for spider alerts.
+type IMSetting struct {
+	DingTalkURL string `json:"dingtalk_url" bson:"dingtalk_url"`
+	FeishuURL   string `json:"feishu_url" bson:"feishu_url"`
+	SlackURL    string `json:"slack_url" bson:"slack_url"`
+}
+
+// postJSON posts payload as JSON to url and treats any non-2xx response as
+// an error. It is shared by the IM notifiers below, which all speak a
+// "webhook URL + JSON body" protocol.
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("IM webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// DingTalkNotifier posts a Message to a DingTalk custom robot webhook as a
+// markdown message.
+type DingTalkNotifier struct {
+	setting *Setting
+	client  *http.Client
+}
+
+// NewDingTalkNotifier builds a DingTalkNotifier from s.IM.DingTalkURL. It
+// satisfies NotifierFactory so it can be registered against ProviderDingTalk.
+func NewDingTalkNotifier(s *Setting) Notifier {
+	return &DingTalkNotifier{setting: s, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *DingTalkNotifier) Send(ctx context.Context, msg Message) error {
+	url := n.setting.IM.DingTalkURL
+	if url == "" {
+		return fmt.Errorf("dingtalk webhook url is empty")
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": msg.Subject,
+			"text":  fmt.Sprintf("#### %s\n%s", msg.Subject, msg.Content),
+		},
+	}
+
+	return postJSON(ctx, n.client, url, payload)
+}
+
+// FeishuNotifier posts a Message to a Feishu (Lark) custom bot webhook as a
+// text message.
+type FeishuNotifier struct {
+	setting *Setting
+	client  *http.Client
+}
+
+// NewFeishuNotifier builds a FeishuNotifier from s.IM.FeishuURL. It
+// satisfies NotifierFactory so it can be registered against ProviderFeishu.
+func NewFeishuNotifier(s *Setting) Notifier {
+	return &FeishuNotifier{setting: s, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *FeishuNotifier) Send(ctx context.Context, msg Message) error {
+	url := n.setting.IM.FeishuURL
+	if url == "" {
+		return fmt.Errorf("feishu webhook url is empty")
+	}
+
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content": map[string]string{
+			"text": fmt.Sprintf("%s\n%s", msg.Subject, msg.Content),
+		},
+	}
+
+	return postJSON(ctx, n.client, url, payload)
+}
+
+// SlackNotifier posts a Message to a Slack incoming webhook.
+type SlackNotifier struct {
+	setting *Setting
+	client  *http.Client
+}
+
+// NewSlackNotifier builds a SlackNotifier from s.IM.SlackURL. It satisfies
+// NotifierFactory so it can be registered against ProviderSlack.
+func NewSlackNotifier(s *Setting) Notifier {
+	return &SlackNotifier{setting: s, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (n *SlackNotifier) Send(ctx context.Context, msg Message) error {
+	url := n.setting.IM.SlackURL
+	if url == "" {
+		return fmt.Errorf("slack webhook url is empty")
+	}
+
+	payload := map[string]interface{}{
+		"text": fmt.Sprintf("*%s*\n%s", msg.Subject, msg.Content),
+	}
+
+	return postJSON(ctx, n.client, url, payload)
+}
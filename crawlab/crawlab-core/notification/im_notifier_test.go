@@ -0,0 +1,84 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDingTalkNotifierSend(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewDingTalkNotifier(&Setting{IM: IMSetting{DingTalkURL: server.URL}})
+	if err := notifier.Send(context.Background(), Message{Subject: "hi", Content: "body"}); err != nil {
+		t.Fatalf("Send returned error: %s", err)
+	}
+	if got["msgtype"] != "markdown" {
+		t.Errorf("msgtype = %v, want markdown", got["msgtype"])
+	}
+}
+
+func TestDingTalkNotifierSendMissingURL(t *testing.T) {
+	notifier := NewDingTalkNotifier(&Setting{})
+	if err := notifier.Send(context.Background(), Message{}); err == nil {
+		t.Fatal("Send with no dingtalk webhook url configured should fail")
+	}
+}
+
+func TestFeishuNotifierSend(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewFeishuNotifier(&Setting{IM: IMSetting{FeishuURL: server.URL}})
+	if err := notifier.Send(context.Background(), Message{Subject: "hi", Content: "body"}); err != nil {
+		t.Fatalf("Send returned error: %s", err)
+	}
+	if got["msg_type"] != "text" {
+		t.Errorf("msg_type = %v, want text", got["msg_type"])
+	}
+}
+
+func TestSlackNotifierSend(t *testing.T) {
+	var got map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewSlackNotifier(&Setting{IM: IMSetting{SlackURL: server.URL}})
+	if err := notifier.Send(context.Background(), Message{Subject: "hi", Content: "body"}); err != nil {
+		t.Fatalf("Send returned error: %s", err)
+	}
+	text, _ := got["text"].(string)
+	if text == "" {
+		t.Error("Slack payload should include a non-empty text field")
+	}
+}
+
+func TestPostJSONNonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := postJSON(context.Background(), http.DefaultClient, server.URL, map[string]string{"a": "b"})
+	if err == nil {
+		t.Fatal("postJSON should return an error for a non-2xx response")
+	}
+}
@@ -1,16 +1,35 @@
 package notification
 
 import (
+	"context"
 	"errors"
-	"github.com/apex/log"
+	"fmt"
+	"github.com/google/uuid"
 	"github.com/matcornic/hermes/v2"
 	"gopkg.in/gomail.v2"
+	"io"
 	"net/mail"
-	"runtime/debug"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// SendMail renders content as a Crawlab-themed email and sends it over SMTP
+// using s.Mail. It is kept as a thin wrapper around SMTPNotifier for callers
+// that don't need provider selection; new code should prefer Dispatch.
 func SendMail(s *Setting, to, cc, title, content string) error {
+	notifier := NewSMTPNotifier(s)
+	return notifier.Send(context.Background(), Message{
+		To:      to,
+		Cc:      cc,
+		Subject: title,
+		Content: content,
+	})
+}
+
+// renderEmail turns markdown content into the html/text bodies shared by all
+// hermes-based senders (SMTPNotifier, MailgunNotifier and the send daemon).
+func renderEmail(content string) (htmlBody string, textBody string, err error) {
 	// theme
 	theme := new(MailThemeFlat)
 
@@ -24,24 +43,6 @@ func SendMail(s *Setting, to, cc, title, content string) error {
 		},
 	}
 
-	// config
-	port, _ := strconv.Atoi(s.Mail.Port)
-	password := s.Mail.Password // test password: ALWVDPRHBEXOENXD
-	SMTPUser := s.Mail.User
-	smtpConfig := smtpAuthentication{
-		Server:         s.Mail.Server,
-		Port:           port,
-		SenderEmail:    s.Mail.SenderEmail,
-		SenderIdentity: s.Mail.SenderIdentity,
-		SMTPPassword:   password,
-		SMTPUser:       SMTPUser,
-	}
-	options := sendOptions{
-		To:      to,
-		Cc:      cc,
-		Subject: title,
-	}
-
 	// add style
 	content += theme.GetStyle()
 
@@ -57,38 +58,30 @@ func SendMail(s *Setting, to, cc, title, content string) error {
 	}
 
 	// generate html
-	html, err := h.GenerateHTML(email)
+	htmlBody, err = h.GenerateHTML(email)
 	if err != nil {
-		log.Errorf(err.Error())
-		debug.PrintStack()
-		return err
+		return "", "", err
 	}
 
 	// generate text
-	text, err := h.GeneratePlainText(email)
+	textBody, err = h.GeneratePlainText(email)
 	if err != nil {
-		log.Errorf(err.Error())
-		debug.PrintStack()
-		return err
+		return "", "", err
 	}
 
-	// send the email
-	if err := send(smtpConfig, options, html, text); err != nil {
-		log.Errorf(err.Error())
-		debug.PrintStack()
-		return err
-	}
-
-	return nil
+	return htmlBody, textBody, nil
 }
 
 type smtpAuthentication struct {
-	Server         string
-	Port           int
-	SenderEmail    string
-	SenderIdentity string
-	SMTPUser       string
-	SMTPPassword   string
+	Server             string
+	Port               int
+	SenderEmail        string
+	SenderIdentity     string
+	SMTPUser           string
+	SMTPPassword       string
+	AuthType           AuthType
+	TLSPolicy          TLSPolicy
+	InsecureSkipVerify bool
 }
 
 // sendOptions are options for sending an email
@@ -96,10 +89,35 @@ type sendOptions struct {
 	To      string
 	Subject string
 	Cc      string
+	// ReplyTo overrides the address replies should go to; left empty, no
+	// Reply-To header is set and replies go to the From address.
+	ReplyTo string
+	// ListUnsubscribeURL, when set, adds a List-Unsubscribe header (plus
+	// List-Unsubscribe-Post for one-click unsubscribe) pointing at it. Only
+	// digest/broadcast sends set this; transactional sends leave it empty.
+	ListUnsubscribeURL string
+	Attachments        []Attachment
 }
 
-// send email
-func send(smtpConfig smtpAuthentication, options sendOptions, htmlBody string, txtBody string) error {
+// smtpAuthenticationFromSetting builds the smtpAuthentication config used by
+// both SMTPNotifier and the send daemon from s.Mail.
+func smtpAuthenticationFromSetting(s *Setting) smtpAuthentication {
+	port, _ := strconv.Atoi(s.Mail.Port)
+	return smtpAuthentication{
+		Server:             s.Mail.Server,
+		Port:               port,
+		SenderEmail:        s.Mail.SenderEmail,
+		SenderIdentity:     s.Mail.SenderIdentity,
+		SMTPPassword:       s.Mail.Password, // test password: ALWVDPRHBEXOENXD
+		SMTPUser:           s.Mail.User,
+		AuthType:           s.Mail.AuthType,
+		TLSPolicy:          s.Mail.TLSPolicy,
+		InsecureSkipVerify: s.Mail.InsecureSkipVerify,
+	}
+}
+
+// validate checks that smtpConfig and options carry everything send needs.
+func (smtpConfig smtpAuthentication) validate(options sendOptions) error {
 	if smtpConfig.Server == "" {
 		return errors.New("SMTP server config is empty")
 	}
@@ -108,7 +126,7 @@ func send(smtpConfig smtpAuthentication, options sendOptions, htmlBody string, t
 		return errors.New("SMTP port config is empty")
 	}
 
-	if smtpConfig.SMTPUser == "" {
+	if smtpConfig.SMTPUser == "" && smtpConfig.AuthType != AuthNone {
 		return errors.New("SMTP user is empty")
 	}
 
@@ -124,6 +142,24 @@ func send(smtpConfig smtpAuthentication, options sendOptions, htmlBody string, t
 		return errors.New("no receiver emails configured")
 	}
 
+	switch smtpConfig.AuthType {
+	case "", AuthPlain, AuthLogin, AuthCRAMMD5, AuthNone:
+	default:
+		return errors.New("unknown SMTP auth type: " + string(smtpConfig.AuthType))
+	}
+
+	switch smtpConfig.TLSPolicy {
+	case "", TLSNone, TLSStartTLS, TLSImplicit:
+	default:
+		return errors.New("unknown SMTP TLS policy: " + string(smtpConfig.TLSPolicy))
+	}
+
+	return nil
+}
+
+// buildMessage assembles the gomail.Message for options/htmlBody/txtBody
+// under smtpConfig's sender identity.
+func buildMessage(smtpConfig smtpAuthentication, options sendOptions, htmlBody string, txtBody string) *gomail.Message {
 	from := mail.Address{
 		Name:    smtpConfig.SenderIdentity,
 		Address: smtpConfig.SenderEmail,
@@ -136,13 +172,67 @@ func send(smtpConfig smtpAuthentication, options sendOptions, htmlBody string, t
 	if options.Cc != "" {
 		m.SetHeader("Cc", options.Cc)
 	}
+	if options.ReplyTo != "" {
+		m.SetHeader("Reply-To", options.ReplyTo)
+	}
+
+	m.SetDateHeader("Date", time.Now())
+	m.SetHeader("Message-Id", messageID(smtpConfig.SenderEmail))
+
+	if options.ListUnsubscribeURL != "" {
+		m.SetHeader("List-Unsubscribe", fmt.Sprintf("<%s>", options.ListUnsubscribeURL))
+		m.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
 
 	m.SetBody("text/plain", txtBody)
 	m.AddAlternative("text/html", htmlBody)
 
-	d := gomail.NewDialer(smtpConfig.Server, smtpConfig.Port, smtpConfig.SMTPUser, smtpConfig.SMTPPassword)
+	for _, a := range options.Attachments {
+		a := a
+		m.Attach(a.Filename,
+			gomail.SetCopyFunc(func(w io.Writer) error {
+				_, err := io.Copy(w, a.Reader)
+				return err
+			}),
+			gomail.SetHeader(map[string][]string{"Content-Type": {a.ContentType}}),
+		)
+	}
+
+	return m
+}
 
-	return d.DialAndSend(m)
+// send email
+func send(smtpConfig smtpAuthentication, options sendOptions, htmlBody string, txtBody string) error {
+	if err := smtpConfig.validate(options); err != nil {
+		return err
+	}
+
+	m := buildMessage(smtpConfig, options, htmlBody, txtBody)
+
+	d, err := newDialer(smtpConfig)
+	if err != nil {
+		return err
+	}
+
+	sc, err := d.Dial()
+	if err != nil {
+		return err
+	}
+	defer sc.Close()
+
+	return gomail.Send(sc, m)
+}
+
+// messageID builds an RFC-2822 Message-ID of the form <uuid@domain>, where
+// domain is taken from senderEmail so recipients see a Message-ID in the
+// sender's own namespace. Missing both "Date" and "Message-ID" is a strong
+// spam signal for SpamAssassin and Gmail.
+func messageID(senderEmail string) string {
+	domain := senderEmail
+	if i := strings.LastIndex(senderEmail, "@"); i != -1 {
+		domain = senderEmail[i+1:]
+	}
+	return fmt.Sprintf("<%s@%s>", uuid.New().String(), domain)
 }
 
 func GetFooter() string {
@@ -0,0 +1,26 @@
+package notification
+
+import (
+	"regexp"
+	"testing"
+)
+
+var messageIDPattern = regexp.MustCompile(`^<[0-9a-f-]{36}@(.+)>$`)
+
+func TestMessageID(t *testing.T) {
+	id := messageID("alerts@crawlab.example.com")
+
+	m := messageIDPattern.FindStringSubmatch(id)
+	if m == nil {
+		t.Fatalf("messageID(...) = %q, want form <uuid@domain>", id)
+	}
+	if m[1] != "crawlab.example.com" {
+		t.Errorf("messageID domain = %q, want %q", m[1], "crawlab.example.com")
+	}
+}
+
+func TestMessageIDUnique(t *testing.T) {
+	if messageID("a@example.com") == messageID("a@example.com") {
+		t.Error("messageID should generate a fresh id on every call")
+	}
+}
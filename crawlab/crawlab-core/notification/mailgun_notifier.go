@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MailgunNotifier sends notifications through the Mailgun HTTP API instead
+// of SMTP. It is configured via Setting.Mailgun.
+type MailgunNotifier struct {
+	setting *Setting
+	client  *http.Client
+}
+
+// MailgunSetting holds the Mailgun API credentials used by MailgunNotifier.
+type MailgunSetting struct {
+	Domain      string `json:"domain" bson:"domain"`
+	APIKey      string `json:"api_key" bson:"api_key"`
+	SenderEmail string `json:"sender_email" bson:"sender_email"`
+}
+
+// NewMailgunNotifier builds a MailgunNotifier from s.Mailgun. It satisfies
+// NotifierFactory so it can be registered against ProviderMailgun.
+func NewMailgunNotifier(s *Setting) Notifier {
+	return &MailgunNotifier{
+		setting: s,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *MailgunNotifier) Send(ctx context.Context, msg Message) error {
+	cfg := n.setting.Mailgun
+	if cfg.Domain == "" {
+		return fmt.Errorf("mailgun domain is empty")
+	}
+	if cfg.APIKey == "" {
+		return fmt.Errorf("mailgun api key is empty")
+	}
+	if cfg.SenderEmail == "" {
+		return fmt.Errorf("mailgun sender email is empty")
+	}
+	if msg.To == "" {
+		return fmt.Errorf("no receiver emails configured")
+	}
+
+	html, text, err := renderEmail(msg.Content)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("https://api.mailgun.net/v3/%s/messages", cfg.Domain)
+	form := url.Values{
+		"from":    {cfg.SenderEmail},
+		"to":      {msg.To},
+		"subject": {msg.Subject},
+		"text":    {text},
+		"html":    {html},
+	}
+	if msg.Cc != "" {
+		form.Set("cc", msg.Cc)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("api", cfg.APIKey)
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("mailgun responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
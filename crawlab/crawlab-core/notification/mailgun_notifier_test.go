@@ -0,0 +1,29 @@
+package notification
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMailgunNotifierSendValidates(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting MailgunSetting
+		to      string
+	}{
+		{"missing domain", MailgunSetting{APIKey: "key", SenderEmail: "a@example.com"}, "to@example.com"},
+		{"missing api key", MailgunSetting{Domain: "example.com", SenderEmail: "a@example.com"}, "to@example.com"},
+		{"missing sender email", MailgunSetting{Domain: "example.com", APIKey: "key"}, "to@example.com"},
+		{"missing recipient", MailgunSetting{Domain: "example.com", APIKey: "key", SenderEmail: "a@example.com"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := NewMailgunNotifier(&Setting{Mailgun: tt.setting})
+			err := notifier.Send(context.Background(), Message{To: tt.to, Subject: "hi", Content: "body"})
+			if err == nil {
+				t.Fatalf("Send(%+v) should have failed validation", tt.setting)
+			}
+		})
+	}
+}
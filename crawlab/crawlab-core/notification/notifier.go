@@ -0,0 +1,94 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+)
+
+// ProviderType identifies a notification channel implementation registered
+// with the package-level registry.
+type ProviderType string
+
+const (
+	ProviderSMTP     ProviderType = "smtp"
+	ProviderMailgun  ProviderType = "mailgun"
+	ProviderWebhook  ProviderType = "webhook"
+	ProviderDingTalk ProviderType = "dingtalk"
+	ProviderFeishu   ProviderType = "feishu"
+	ProviderSlack    ProviderType = "slack"
+)
+
+// Message is the channel-agnostic payload dispatched to a Notifier. Not every
+// field is meaningful to every provider: IM providers typically ignore Cc,
+// for instance.
+type Message struct {
+	To      string
+	Cc      string
+	Subject string
+	Content string
+	// ReplyTo overrides the address replies should go to.
+	ReplyTo string
+	// ListUnsubscribeURL, when set, adds List-Unsubscribe headers pointing
+	// at it. Used by digest/broadcast sends, left empty otherwise.
+	ListUnsubscribeURL string
+	Attachments        []Attachment
+}
+
+// Notifier is implemented by every notification channel (SMTP, Mailgun,
+// generic webhooks, IM bots, ...). Send should treat ctx as cancellable and
+// return a non-nil error on failure so callers can retry or log.
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NotifierFactory builds a Notifier from a Setting. It is registered against
+// a ProviderType via Register.
+type NotifierFactory func(s *Setting) Notifier
+
+var notifierFactories = map[ProviderType]NotifierFactory{}
+
+func init() {
+	Register(ProviderSMTP, NewSMTPNotifier)
+	Register(ProviderMailgun, NewMailgunNotifier)
+	Register(ProviderWebhook, NewWebhookNotifier)
+	Register(ProviderDingTalk, NewDingTalkNotifier)
+	Register(ProviderFeishu, NewFeishuNotifier)
+	Register(ProviderSlack, NewSlackNotifier)
+}
+
+// Register associates a ProviderType with a factory. The built-in providers
+// are registered from this file's init().
+func Register(provider ProviderType, factory NotifierFactory) {
+	notifierFactories[provider] = factory
+}
+
+// resolvedProvider returns s.Provider, falling back to ProviderSMTP when it
+// is empty so existing settings that predate the Provider field keep
+// working.
+func resolvedProvider(s *Setting) ProviderType {
+	if s.Provider == "" {
+		return ProviderSMTP
+	}
+	return s.Provider
+}
+
+// GetNotifier resolves the Notifier configured on s.Provider. It falls back
+// to ProviderSMTP when s.Provider is empty so existing settings that predate
+// the Provider field keep working.
+func GetNotifier(s *Setting) (Notifier, error) {
+	provider := resolvedProvider(s)
+	factory, ok := notifierFactories[provider]
+	if !ok {
+		return nil, fmt.Errorf("no notifier registered for provider: %s", provider)
+	}
+	return factory(s), nil
+}
+
+// Dispatch resolves the Notifier configured on s and sends msg through it.
+func Dispatch(ctx context.Context, s *Setting, msg Message) error {
+	notifier, err := GetNotifier(s)
+	if err != nil {
+		return err
+	}
+	return notifier.Send(ctx, msg)
+}
@@ -0,0 +1,54 @@
+package notification
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGetNotifierDefaultsToSMTP(t *testing.T) {
+	n, err := GetNotifier(&Setting{})
+	if err != nil {
+		t.Fatalf("GetNotifier(empty provider) returned error: %s", err)
+	}
+	if _, ok := n.(*SMTPNotifier); !ok {
+		t.Fatalf("GetNotifier(empty provider) = %T, want *SMTPNotifier", n)
+	}
+}
+
+func TestGetNotifierUnknownProvider(t *testing.T) {
+	if _, err := GetNotifier(&Setting{Provider: "bogus"}); err == nil {
+		t.Fatal("GetNotifier with an unregistered provider should return an error")
+	}
+}
+
+func TestDispatchRoutesToRegisteredProvider(t *testing.T) {
+	const testProvider ProviderType = "test-dispatch"
+
+	var gotMsg Message
+	Register(testProvider, func(s *Setting) Notifier {
+		return notifierFunc(func(ctx context.Context, msg Message) error {
+			gotMsg = msg
+			return nil
+		})
+	})
+
+	msg := Message{To: "user@example.com", Subject: "hi"}
+	if err := Dispatch(context.Background(), &Setting{Provider: testProvider}, msg); err != nil {
+		t.Fatalf("Dispatch returned error: %s", err)
+	}
+	if gotMsg.To != msg.To || gotMsg.Subject != msg.Subject {
+		t.Errorf("Dispatch delivered %+v, want %+v", gotMsg, msg)
+	}
+}
+
+func TestDispatchUnknownProvider(t *testing.T) {
+	if err := Dispatch(context.Background(), &Setting{Provider: "bogus"}, Message{}); err == nil {
+		t.Fatal("Dispatch with an unregistered provider should return an error")
+	}
+}
+
+// notifierFunc adapts a plain function to the Notifier interface, for tests
+// that don't need a full provider implementation.
+type notifierFunc func(ctx context.Context, msg Message) error
+
+func (f notifierFunc) Send(ctx context.Context, msg Message) error { return f(ctx, msg) }
@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+
+	"gopkg.in/gomail.v2"
+)
+
+// smtpDialer is the subset of *gomail.Dialer's behavior newDialer needs.
+// *gomail.Dialer satisfies it as-is; plainDialer is a from-scratch
+// implementation used for TLSNone, since gomail.Dialer has no way to
+// suppress the opportunistic STARTTLS it always attempts when a server
+// advertises the extension (see newDialer's doc comment).
+type smtpDialer interface {
+	Dial() (gomail.SendCloser, error)
+}
+
+// plainDialer dials a plaintext SMTP connection and never attempts
+// STARTTLS, even if the server advertises it — the genuine "no TLS" policy
+// TLSNone promises. It should only be pointed at trusted relays (e.g. one
+// on localhost or an internal network) since credentials and message
+// content cross the wire unencrypted. With AuthPlain (or an unset AuthType)
+// Dial fails unless the server is localhost, because net/smtp's PlainAuth
+// refuses to send credentials over an unencrypted connection to any other
+// host; AuthLogin, AuthCRAMMD5 and AuthNone have no such check.
+type plainDialer struct {
+	cfg smtpAuthentication
+}
+
+func (d *plainDialer) Dial() (gomail.SendCloser, error) {
+	addr := fmt.Sprintf("%s:%d", d.cfg.Server, d.cfg.Port)
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := smtp.NewClient(conn, d.cfg.Server)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	auth, err := newSMTPAuth(d.cfg)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return &plainConn{client: client}, nil
+}
+
+// plainConn adapts an *smtp.Client, dialed without TLS, to gomail.SendCloser.
+type plainConn struct {
+	client *smtp.Client
+}
+
+func (c *plainConn) Send(from string, to []string, msg io.WriterTo) error {
+	if err := c.client.Mail(from); err != nil {
+		return err
+	}
+	for _, addr := range to {
+		if err := c.client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := msg.WriteTo(w); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func (c *plainConn) Close() error {
+	return c.client.Quit()
+}
@@ -0,0 +1,62 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+)
+
+// ResultFormat selects how a task's results are serialized before being
+// attached to an email.
+type ResultFormat string
+
+const (
+	ResultFormatCSV   ResultFormat = "csv"
+	ResultFormatJSON  ResultFormat = "json"
+	ResultFormatExcel ResultFormat = "xlsx"
+)
+
+// resultContentTypes maps each ResultFormat to the MIME type used on the
+// attachment.
+var resultContentTypes = map[ResultFormat]string{
+	ResultFormatCSV:   "text/csv",
+	ResultFormatJSON:  "application/json",
+	ResultFormatExcel: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// ResultExporter streams a task's result set, serialized as format, so it
+// can be attached to a notification email. Implementations live alongside
+// the task/result models, which know how to page through a task's results.
+type ResultExporter interface {
+	ExportTaskResults(ctx context.Context, taskId string, format ResultFormat) (io.Reader, error)
+}
+
+// SendTaskResultsMail emails the results of taskId as an attachment,
+// turning a notification into a small reporting channel instead of just a
+// status ping.
+func SendTaskResultsMail(ctx context.Context, s *Setting, exporter ResultExporter, taskId, to, cc, title, content string, format ResultFormat) error {
+	contentType, ok := resultContentTypes[format]
+	if !ok {
+		return fmt.Errorf("unknown result format: %s", format)
+	}
+
+	reader, err := exporter.ExportTaskResults(ctx, taskId, format)
+	if err != nil {
+		return err
+	}
+
+	attachment := Attachment{
+		Filename:    fmt.Sprintf("task-%s-results.%s", taskId, format),
+		ContentType: contentType,
+		Reader:      reader,
+	}
+
+	notifier := NewSMTPNotifier(s)
+	return notifier.Send(ctx, Message{
+		To:          to,
+		Cc:          cc,
+		Subject:     title,
+		Content:     content,
+		Attachments: []Attachment{attachment},
+	})
+}
@@ -0,0 +1,42 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+type fakeResultExporter struct {
+	reader io.Reader
+	err    error
+}
+
+func (f *fakeResultExporter) ExportTaskResults(ctx context.Context, taskId string, format ResultFormat) (io.Reader, error) {
+	return f.reader, f.err
+}
+
+func TestSendTaskResultsMailUnknownFormat(t *testing.T) {
+	exporter := &fakeResultExporter{}
+	err := SendTaskResultsMail(context.Background(), &Setting{}, exporter, "task-1", "to@example.com", "", "title", "body", "bogus")
+	if err == nil {
+		t.Fatal("SendTaskResultsMail with an unknown format should fail before exporting")
+	}
+}
+
+func TestSendTaskResultsMailExportError(t *testing.T) {
+	exporter := &fakeResultExporter{err: errors.New("export failed")}
+	err := SendTaskResultsMail(context.Background(), &Setting{}, exporter, "task-1", "to@example.com", "", "title", "body", ResultFormatCSV)
+	if err == nil {
+		t.Fatal("SendTaskResultsMail should propagate an export error")
+	}
+}
+
+func TestSendTaskResultsMailValidatesBeforeDialing(t *testing.T) {
+	exporter := &fakeResultExporter{reader: bytes.NewReader([]byte("a,b\n1,2\n"))}
+	err := SendTaskResultsMail(context.Background(), &Setting{}, exporter, "task-1", "to@example.com", "", "title", "body", ResultFormatCSV)
+	if err == nil {
+		t.Fatal("SendTaskResultsMail with no SMTP server configured should fail validation before dialing")
+	}
+}
@@ -0,0 +1,31 @@
+package notification
+
+// Setting holds the notification configuration for a user or a system-wide
+// default. Provider selects which registered Notifier handles Send/Dispatch
+// calls; it defaults to ProviderSMTP for settings created before the
+// multi-provider refactor.
+type Setting struct {
+	Provider ProviderType   `json:"provider" bson:"provider"`
+	Mail     MailSetting    `json:"mail" bson:"mail"`
+	Mailgun  MailgunSetting `json:"mailgun" bson:"mailgun"`
+	Webhook  WebhookSetting `json:"webhook" bson:"webhook"`
+	IM       IMSetting      `json:"im" bson:"im"`
+}
+
+// MailSetting holds SMTP connection details used by SMTPNotifier.
+type MailSetting struct {
+	Server         string `json:"server" bson:"server"`
+	Port           string `json:"port" bson:"port"`
+	User           string `json:"user" bson:"user"`
+	Password       string `json:"password" bson:"password"`
+	SenderEmail    string `json:"sender_email" bson:"sender_email"`
+	SenderIdentity string `json:"sender_identity" bson:"sender_identity"`
+
+	// AuthType selects the SMTP auth mechanism; defaults to AuthPlain.
+	AuthType AuthType `json:"auth_type" bson:"auth_type"`
+	// TLSPolicy selects how the connection is secured; defaults to TLSStartTLS.
+	TLSPolicy TLSPolicy `json:"tls_policy" bson:"tls_policy"`
+	// InsecureSkipVerify disables TLS certificate verification, for relays
+	// on internal networks with self-signed certs.
+	InsecureSkipVerify bool `json:"insecure_skip_verify" bson:"insecure_skip_verify"`
+}
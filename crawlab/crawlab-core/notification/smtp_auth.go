@@ -0,0 +1,122 @@
+package notification
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/smtp"
+
+	"gopkg.in/gomail.v2"
+)
+
+// AuthType selects the SMTP authentication mechanism gomail's dialer uses.
+// gomail defaults to PLAIN, which several relays (Office365/Exchange among
+// them) reject outright.
+type AuthType string
+
+const (
+	AuthPlain   AuthType = "plain"
+	AuthLogin   AuthType = "login"
+	AuthCRAMMD5 AuthType = "crammd5"
+	AuthNone    AuthType = "none"
+)
+
+// TLSPolicy selects how the SMTP connection is secured.
+type TLSPolicy string
+
+const (
+	// TLSNone forces a genuine plaintext connection: no implicit TLS, and
+	// no opportunistic STARTTLS even if the server advertises it. Only
+	// useful talking to a trusted local/internal relay, since gomail's own
+	// dialer has no field for this — it always attempts STARTTLS when
+	// offered — so this policy is served by plainDialer instead.
+	TLSNone TLSPolicy = "none"
+	// TLSStartTLS upgrades a plaintext connection via STARTTLS, the common
+	// choice on port 587.
+	TLSStartTLS TLSPolicy = "starttls"
+	// TLSImplicit dials straight into TLS, the common choice on port 465.
+	TLSImplicit TLSPolicy = "implicit"
+)
+
+// newDialer resolves cfg.TLSPolicy into a smtpDialer: a gomail.Dialer,
+// wired up with the configured auth mechanism, for TLSStartTLS/TLSImplicit
+// (and the unset default), or a plainDialer for TLSNone, which gomail
+// cannot express since it always attempts STARTTLS opportunistically.
+func newDialer(cfg smtpAuthentication) (smtpDialer, error) {
+	if cfg.TLSPolicy == TLSNone {
+		return &plainDialer{cfg: cfg}, nil
+	}
+
+	// gomail picks an auth mechanism itself whenever Auth is nil and a
+	// username is set, so AuthNone also clears the username.
+	user := cfg.SMTPUser
+	if cfg.AuthType == AuthNone {
+		user = ""
+	}
+	d := gomail.NewDialer(cfg.Server, cfg.Port, user, cfg.SMTPPassword)
+
+	auth, err := newSMTPAuth(cfg)
+	if err != nil {
+		return nil, err
+	}
+	d.Auth = auth
+
+	switch cfg.TLSPolicy {
+	case TLSImplicit:
+		d.SSL = true
+	case TLSStartTLS, "":
+		// gomail's default: opportunistic STARTTLS.
+	default:
+		return nil, errors.New("unknown SMTP TLS policy: " + string(cfg.TLSPolicy))
+	}
+
+	if cfg.InsecureSkipVerify {
+		d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return d, nil
+}
+
+// newSMTPAuth resolves cfg.AuthType into the smtp.Auth gomail should use.
+// A nil smtp.Auth, returned for AuthNone, makes the dialers skip
+// authentication entirely. An unset AuthType defaults to PLAIN so existing
+// settings keep working.
+func newSMTPAuth(cfg smtpAuthentication) (smtp.Auth, error) {
+	switch cfg.AuthType {
+	case AuthNone:
+		return nil, nil
+	case AuthLogin:
+		return &loginAuth{username: cfg.SMTPUser, password: cfg.SMTPPassword}, nil
+	case AuthCRAMMD5:
+		return smtp.CRAMMD5Auth(cfg.SMTPUser, cfg.SMTPPassword), nil
+	case AuthPlain, "":
+		return smtp.PlainAuth("", cfg.SMTPUser, cfg.SMTPPassword, cfg.Server), nil
+	default:
+		return nil, errors.New("unknown SMTP auth type: " + string(cfg.AuthType))
+	}
+}
+
+// loginAuth implements the SMTP LOGIN mechanism, which the standard library
+// does not ship (net/smtp only provides PLAIN and CRAM-MD5), but which is
+// required by several relays such as Office365/Exchange.
+type loginAuth struct {
+	username string
+	password string
+}
+
+func (a *loginAuth) Start(_ *smtp.ServerInfo) (string, []byte, error) {
+	return "LOGIN", []byte(a.username), nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch string(fromServer) {
+	case "Username:":
+		return []byte(a.username), nil
+	case "Password:":
+		return []byte(a.password), nil
+	default:
+		return nil, errors.New("unexpected SMTP LOGIN server prompt: " + string(fromServer))
+	}
+}
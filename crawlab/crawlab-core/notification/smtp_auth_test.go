@@ -0,0 +1,85 @@
+package notification
+
+import (
+	"testing"
+)
+
+func TestNewSMTPAuth(t *testing.T) {
+	cfg := smtpAuthentication{
+		Server:       "smtp.example.com",
+		SMTPUser:     "user",
+		SMTPPassword: "pass",
+	}
+
+	tests := []struct {
+		name     string
+		authType AuthType
+		wantNil  bool
+	}{
+		{"unset defaults to PLAIN", "", false},
+		{"explicit PLAIN", AuthPlain, false},
+		{"LOGIN", AuthLogin, false},
+		{"CRAM-MD5", AuthCRAMMD5, false},
+		{"none skips auth", AuthNone, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := cfg
+			cfg.AuthType = tt.authType
+
+			auth, err := newSMTPAuth(cfg)
+			if err != nil {
+				t.Fatalf("newSMTPAuth(%q) returned error: %s", tt.authType, err)
+			}
+
+			if tt.wantNil {
+				if auth != nil {
+					t.Fatalf("newSMTPAuth(%q) = %T, want nil", tt.authType, auth)
+				}
+				return
+			}
+
+			if auth == nil {
+				t.Fatalf("newSMTPAuth(%q) = nil, want non-nil", tt.authType)
+			}
+		})
+	}
+
+	if _, err := newSMTPAuth(smtpAuthentication{AuthType: "bogus"}); err == nil {
+		t.Fatal("newSMTPAuth with an unknown AuthType should return an error")
+	}
+}
+
+func TestLoginAuth(t *testing.T) {
+	auth := &loginAuth{username: "user", password: "pass"}
+
+	proto, toServer, err := auth.Start(nil)
+	if err != nil {
+		t.Fatalf("Start returned error: %s", err)
+	}
+	if proto != "LOGIN" {
+		t.Fatalf("Start proto = %q, want LOGIN", proto)
+	}
+	if string(toServer) != "user" {
+		t.Fatalf("Start toServer = %q, want %q", toServer, "user")
+	}
+
+	reply, err := auth.Next([]byte("Username:"), true)
+	if err != nil || string(reply) != "user" {
+		t.Fatalf("Next(Username:) = (%q, %v), want (%q, nil)", reply, err, "user")
+	}
+
+	reply, err = auth.Next([]byte("Password:"), true)
+	if err != nil || string(reply) != "pass" {
+		t.Fatalf("Next(Password:) = (%q, %v), want (%q, nil)", reply, err, "pass")
+	}
+
+	if reply, err := auth.Next(nil, false); err != nil || reply != nil {
+		t.Fatalf("Next with more=false = (%q, %v), want (nil, nil)", reply, err)
+	}
+
+	if _, err := auth.Next([]byte("Unexpected:"), true); err == nil {
+		t.Fatal("Next with an unexpected server prompt should return an error")
+	}
+}
@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"context"
+	"github.com/apex/log"
+	"runtime/debug"
+)
+
+// SMTPNotifier sends notifications as Crawlab-themed emails over SMTP. It is
+// the default Notifier and backs the legacy SendMail entry point.
+type SMTPNotifier struct {
+	setting *Setting
+}
+
+// NewSMTPNotifier builds a SMTPNotifier from s.Mail. It satisfies
+// NotifierFactory so it can be registered against ProviderSMTP.
+func NewSMTPNotifier(s *Setting) Notifier {
+	return &SMTPNotifier{setting: s}
+}
+
+func (n *SMTPNotifier) Send(ctx context.Context, msg Message) error {
+	smtpConfig := smtpAuthenticationFromSetting(n.setting)
+	options := sendOptions{
+		To:                 msg.To,
+		Cc:                 msg.Cc,
+		Subject:            msg.Subject,
+		ReplyTo:            msg.ReplyTo,
+		ListUnsubscribeURL: msg.ListUnsubscribeURL,
+		Attachments:        msg.Attachments,
+	}
+
+	// generate html/text bodies
+	html, text, err := renderEmail(msg.Content)
+	if err != nil {
+		log.Errorf(err.Error())
+		debug.PrintStack()
+		return err
+	}
+
+	// send the email
+	if err := send(smtpConfig, options, html, text); err != nil {
+		log.Errorf(err.Error())
+		debug.PrintStack()
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package notification
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSMTPNotifierSendValidatesBeforeDialing(t *testing.T) {
+	notifier := NewSMTPNotifier(&Setting{})
+
+	err := notifier.Send(context.Background(), Message{To: "user@example.com"})
+	if err == nil {
+		t.Fatal("Send with no SMTP server configured should fail validation before dialing")
+	}
+}
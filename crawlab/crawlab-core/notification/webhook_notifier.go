@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// WebhookSetting holds the target URL and signing secret used by
+// WebhookNotifier.
+type WebhookSetting struct {
+	URL    string `json:"url" bson:"url"`
+	Secret string `json:"secret" bson:"secret"`
+}
+
+// webhookPayload is the JSON body posted to Setting.Webhook.URL.
+type webhookPayload struct {
+	To      string `json:"to"`
+	Cc      string `json:"cc,omitempty"`
+	Subject string `json:"subject"`
+	Content string `json:"content"`
+}
+
+// WebhookNotifier posts a Message as JSON to a user-configured URL, signing
+// the body with HMAC-SHA256 so the receiver can verify it came from Crawlab.
+type WebhookNotifier struct {
+	setting *Setting
+	client  *http.Client
+}
+
+// NewWebhookNotifier builds a WebhookNotifier from s.Webhook. It satisfies
+// NotifierFactory so it can be registered against ProviderWebhook.
+func NewWebhookNotifier(s *Setting) Notifier {
+	return &WebhookNotifier{
+		setting: s,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (n *WebhookNotifier) Send(ctx context.Context, msg Message) error {
+	cfg := n.setting.Webhook
+	if cfg.URL == "" {
+		return fmt.Errorf("webhook url is empty")
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		To:      msg.To,
+		Cc:      msg.Cc,
+		Subject: msg.Subject,
+		Content: msg.Content,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if cfg.Secret != "" {
+		req.Header.Set("X-Crawlab-Signature", signWebhookBody(cfg.Secret, body))
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+// signWebhookBody returns the hex-encoded HMAC-SHA256 of body keyed by
+// secret, in the same "sha256=<hex>" form GitHub/Stripe webhooks use.
+func signWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,71 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSignWebhookBody(t *testing.T) {
+	sig := signWebhookBody("secret", []byte("payload"))
+	if sig != "sha256=b82fcb791acec57859b989b430a826488ce2e479fdf92326bd0a2e8375a42ba4" {
+		t.Errorf("signWebhookBody = %q, want the known HMAC-SHA256 of \"payload\" under \"secret\"", sig)
+	}
+
+	// Same body, same secret, same signature.
+	if signWebhookBody("secret", []byte("payload")) != sig {
+		t.Error("signWebhookBody should be deterministic for the same inputs")
+	}
+	// Different secret, different signature.
+	if signWebhookBody("other", []byte("payload")) == sig {
+		t.Error("signWebhookBody should depend on the secret")
+	}
+}
+
+func TestWebhookNotifierSend(t *testing.T) {
+	var gotPayload webhookPayload
+	var gotSig string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSig = r.Header.Get("X-Crawlab-Signature")
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &gotPayload)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(&Setting{Webhook: WebhookSetting{URL: server.URL, Secret: "secret"}})
+	msg := Message{To: "user@example.com", Cc: "cc@example.com", Subject: "hi", Content: "body"}
+
+	if err := notifier.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send returned error: %s", err)
+	}
+	if gotPayload.To != msg.To || gotPayload.Subject != msg.Subject || gotPayload.Content != msg.Content {
+		t.Errorf("server received %+v, want payload built from %+v", gotPayload, msg)
+	}
+	if gotSig == "" {
+		t.Error("Send should sign the body when a secret is configured")
+	}
+}
+
+func TestWebhookNotifierSendMissingURL(t *testing.T) {
+	notifier := NewWebhookNotifier(&Setting{})
+	if err := notifier.Send(context.Background(), Message{To: "user@example.com"}); err == nil {
+		t.Fatal("Send with no webhook URL configured should fail")
+	}
+}
+
+func TestWebhookNotifierSendNonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(&Setting{Webhook: WebhookSetting{URL: server.URL}})
+	if err := notifier.Send(context.Background(), Message{To: "user@example.com"}); err == nil {
+		t.Fatal("Send should fail when the webhook responds with a non-2xx status")
+	}
+}